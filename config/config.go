@@ -0,0 +1,14 @@
+package config
+
+// Config is the root configuration blocky is started with. It only lists
+// the fields the resolver/server packages' builders need; the rest of the
+// real configuration (ports, logging, etc.) lives outside this checkout.
+type Config struct {
+	CustomDNS     CustomDNSConfig     `yaml:"customDNS"`
+	ContainerDNS  ContainerDNSConfig  `yaml:"containerDNS"`
+	QueryStrategy QueryStrategyConfig `yaml:"queryStrategy"`
+	Upstreams     []Upstream          `yaml:"upstreams"`
+
+	// DoQListener is nil unless a DNS-over-QUIC listener is configured.
+	DoQListener *DoQListener `yaml:"doqListener"`
+}
@@ -0,0 +1,22 @@
+package config
+
+// ContainerDNSConfig configures ContainerDNSResolver, which gives zero-config
+// DNS for local Docker/Podman containers.
+type ContainerDNSConfig struct {
+	// SocketPath is the Docker/Podman engine API socket, e.g.
+	// "unix:///var/run/docker.sock".
+	SocketPath string `yaml:"socketPath"`
+
+	// Domain is appended to the container name to build its DNS name, e.g.
+	// "docker" turns container "web" into "web.docker".
+	Domain string `yaml:"domain"`
+
+	// LabelFilter restricts which containers are mapped, using the same
+	// "key=value" syntax as `docker ps --filter label=...`. Empty means all
+	// containers are mapped.
+	LabelFilter []string `yaml:"labelFilter"`
+
+	// CustomTTL is the TTL used for answers synthesized from the container
+	// mapping.
+	CustomTTL Duration `yaml:"customTTL"`
+}
@@ -0,0 +1,29 @@
+package config
+
+import "net"
+
+// CustomDNSConfig configures CustomDNSResolver.
+type CustomDNSConfig struct {
+	Mapping   CustomDNSMapping `yaml:"mapping"`
+	CustomTTL Duration         `yaml:"customTTL"`
+
+	// HostsFilePaths and ZoneFilePaths additionally seed (and, via an
+	// fsnotify watch, keep live-reloaded into) the resolver's mapping from
+	// /etc/hosts-style files and RFC 1035 zone files respectively.
+	HostsFilePaths []string `yaml:"hostsFilePaths"`
+	ZoneFilePaths  []string `yaml:"zoneFilePaths"`
+}
+
+// CustomDNSMapping holds the statically configured domain -> answer entries.
+type CustomDNSMapping struct {
+	HostIPs map[string][]net.IP `yaml:"hostIPs"`
+
+	// CNAMEs maps a domain to another domain to resolve through instead of
+	// an IP; CustomDNSResolver follows the chain (bounded, loop-checked)
+	// before falling through to the next resolver.
+	CNAMEs map[string]string `yaml:"cnames"`
+
+	// TTLs overrides CustomTTL for individual mapping entries, keyed the
+	// same way as HostIPs/CNAMEs.
+	TTLs map[string]Duration `yaml:"ttls"`
+}
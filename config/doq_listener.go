@@ -0,0 +1,11 @@
+package config
+
+// DoQListener configures the DNS-over-QUIC (RFC 9250) server-side listener:
+// the counterpart to an upstream entry with `net: quic`.
+type DoQListener struct {
+	// Address is the `host:port` the listener binds, e.g. ":853".
+	Address string `yaml:"address"`
+
+	CertFile string `yaml:"certFile"`
+	KeyFile  string `yaml:"keyFile"`
+}
@@ -0,0 +1,7 @@
+package config
+
+import "time"
+
+// Duration is a time.Duration that can be used directly as a config struct
+// field; resolvers convert it back with time.Duration(cfg.SomeDuration).
+type Duration time.Duration
@@ -0,0 +1,23 @@
+package config
+
+// QueryStrategy selects which IP family answers are allowed to contain.
+type QueryStrategy string
+
+const (
+	// QueryStrategyUseIP allows both A and AAAA answers through unfiltered.
+	QueryStrategyUseIP QueryStrategy = "useIP"
+	// QueryStrategyUseIPv4 drops AAAA answers.
+	QueryStrategyUseIPv4 QueryStrategy = "useIPv4"
+	// QueryStrategyUseIPv6 drops A answers.
+	QueryStrategyUseIPv6 QueryStrategy = "useIPv6"
+)
+
+// QueryStrategyConfig configures QueryStrategyResolver.
+type QueryStrategyConfig struct {
+	QueryStrategy QueryStrategy `yaml:"queryStrategy"`
+
+	// ClientGroupsStrategy overrides QueryStrategy for specific client
+	// groups, keyed by client name (matched case-insensitively). Client IPs
+	// and CIDRs are not matched against these keys.
+	ClientGroupsStrategy map[string]QueryStrategy `yaml:"clientGroupsStrategy"`
+}
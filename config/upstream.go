@@ -0,0 +1,13 @@
+package config
+
+// Upstream describes a single upstream DNS server, e.g. `quic://dns.example.com:853`.
+type Upstream struct {
+	Net  string `yaml:"net"`
+	Host string `yaml:"host"`
+	Port uint16 `yaml:"port"`
+	Path string `yaml:"path"`
+
+	// Timeout bounds how long a single query to this upstream may take
+	// before it's treated as failed.
+	Timeout Duration `yaml:"timeout"`
+}
@@ -0,0 +1,51 @@
+// Package evt provides a small process-wide publish/subscribe event bus so
+// unrelated packages (e.g. resolvers and the web UI) can react to state
+// changes - like a config reload - without depending on each other directly.
+package evt
+
+import (
+	"sync"
+)
+
+// Bus returns the process-wide event bus.
+func Bus() *EventBus {
+	busOnce.Do(func() {
+		busInstance = newEventBus()
+	})
+
+	return busInstance
+}
+
+var (
+	busInstance *EventBus
+	busOnce     sync.Once
+)
+
+// EventBus is a minimal synchronous topic -> subscribers dispatcher.
+type EventBus struct {
+	mu          sync.RWMutex
+	subscribers map[string][]func(...interface{})
+}
+
+func newEventBus() *EventBus {
+	return &EventBus{subscribers: make(map[string][]func(...interface{}))}
+}
+
+// Subscribe registers fn to be called whenever topic is published.
+func (b *EventBus) Subscribe(topic string, fn func(...interface{})) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.subscribers[topic] = append(b.subscribers[topic], fn)
+}
+
+// Publish calls every subscriber of topic with args.
+func (b *EventBus) Publish(topic string, args ...interface{}) {
+	b.mu.RLock()
+	subscribers := append([]func(...interface{}){}, b.subscribers[topic]...)
+	b.mu.RUnlock()
+
+	for _, fn := range subscribers {
+		fn(args...)
+	}
+}
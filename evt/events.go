@@ -0,0 +1,10 @@
+package evt
+
+// CustomDNSFileReloaded fires after CustomDNSResolver finishes reloading its
+// configured hosts/zone files, with the number of host (A/AAAA) and CNAME
+// entries now loaded as its argument; TXT and SRV records are not counted.
+const CustomDNSFileReloaded = "customDNS:fileReloaded"
+
+// ContainerDNSMappingChanged fires whenever ContainerDNSResolver adds or
+// removes a container's mapping, with the affected hostname as its argument.
+const ContainerDNSMappingChanged = "containerDNS:mappingChanged"
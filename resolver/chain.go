@@ -0,0 +1,55 @@
+package resolver
+
+import (
+	"github.com/0xERR0R/blocky/config"
+)
+
+// NewResolverChain assembles the resolvers added in this series into the
+// chain the bootstrap/server code is expected to install. QueryStrategyResolver
+// goes first (outermost) since it has to filter whatever comes back from
+// every other resolver - CustomDNSResolver, ContainerDNSResolver, or the
+// upstream - and both CustomDNSResolver and ContainerDNSResolver return
+// directly on a mapping hit without ever calling r.next, so placing
+// QueryStrategyResolver anywhere behind them would leave their answers
+// unfiltered. CustomDNSResolver and ContainerDNSResolver answer from their
+// own mappings next, and a DoQUpstreamResolver is used as the final upstream
+// if one is configured with `net: quic`.
+//
+// This is the entry point the bootstrap package (outside this checkout) is
+// expected to call instead of constructing these resolvers itself - without
+// it, NewContainerDNSResolver/NewQueryStrategyResolver/NewDoQUpstreamResolver
+// were unreachable from any config path.
+func NewResolverChain(cfg *config.Config) (Resolver, error) {
+	resolvers := []Resolver{
+		NewQueryStrategyResolver(cfg.QueryStrategy),
+		NewCustomDNSResolver(cfg.CustomDNS),
+		NewContainerDNSResolver(cfg.ContainerDNS),
+	}
+
+	upstream, err := newQUICUpstreamResolver(cfg.Upstreams)
+	if err != nil {
+		return nil, err
+	}
+
+	if upstream != nil {
+		resolvers = append(resolvers, upstream)
+	}
+
+	return Chain(resolvers...), nil
+}
+
+// newQUICUpstreamResolver picks the first `net: quic` upstream, if any, and
+// wraps it in a DoQUpstreamResolver. Other upstream transports (plain
+// UDP/TCP, DoT, DoH) are assembled by the existing upstream resolver, which
+// isn't part of this checkout.
+func newQUICUpstreamResolver(upstreams []config.Upstream) (Resolver, error) {
+	for _, u := range upstreams {
+		if u.Net != "quic" {
+			continue
+		}
+
+		return NewDoQUpstreamResolver(u, nil)
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,57 @@
+package resolver
+
+import (
+	"net"
+	"testing"
+
+	"github.com/0xERR0R/blocky/config"
+	"github.com/0xERR0R/blocky/model"
+
+	"github.com/miekg/dns"
+)
+
+// TestChainFiltersCustomDNSAnswer proves that QueryStrategyResolver sits
+// outermost in NewResolverChain's ordering: a AAAA answer sourced from
+// CustomDNSResolver's own mapping (which returns directly, without ever
+// calling r.next) still reaches QueryStrategyResolver and gets filtered,
+// instead of only ever seeing the upstream's answer.
+func TestChainFiltersCustomDNSAnswer(t *testing.T) {
+	customDNS, ok := NewCustomDNSResolver(config.CustomDNSConfig{
+		Mapping: config.CustomDNSMapping{
+			HostIPs: map[string][]net.IP{"example.com": {net.ParseIP("2001:db8::1")}},
+		},
+	}).(*CustomDNSResolver)
+	if !ok {
+		t.Fatal("NewCustomDNSResolver did not return a *CustomDNSResolver")
+	}
+
+	queryStrategy, ok := NewQueryStrategyResolver(config.QueryStrategyConfig{
+		QueryStrategy: config.QueryStrategyUseIPv4,
+	}).(*QueryStrategyResolver)
+	if !ok {
+		t.Fatal("NewQueryStrategyResolver did not return a *QueryStrategyResolver")
+	}
+
+	// Wire QueryStrategyResolver outermost, exactly like NewResolverChain does.
+	queryStrategy.next = customDNS
+
+	msg := new(dns.Msg)
+	msg.SetQuestion(dns.Fqdn("example.com"), dns.TypeAAAA)
+
+	resp, err := queryStrategy.Resolve(&model.Request{Req: msg})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if resp == nil || resp.Res == nil {
+		t.Fatal("expected a response, got nil")
+	}
+
+	if len(resp.Res.Answer) != 0 {
+		t.Fatalf("expected the AAAA answer from CustomDNSResolver to be filtered out by QueryStrategyResolver, got %v", resp.Res.Answer)
+	}
+
+	if resp.Res.Rcode != dns.RcodeSuccess {
+		t.Fatalf("expected RcodeSuccess after filtering to empty, got %d", resp.Res.Rcode)
+	}
+}
@@ -0,0 +1,340 @@
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/0xERR0R/blocky/config"
+	"github.com/0xERR0R/blocky/evt"
+	"github.com/0xERR0R/blocky/model"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/client"
+	"github.com/miekg/dns"
+)
+
+// containerEventsMinBackoff/containerEventsMaxBackoff bound the reconnect
+// delay used by watch() whenever the Docker/Podman event stream ends
+// unexpectedly (daemon restart, socket hiccup, etc.).
+const (
+	containerEventsMinBackoff = time.Second
+	containerEventsMaxBackoff = 30 * time.Second
+)
+
+// ContainerDNSResolver gives zero-config DNS for local Docker/Podman
+// containers: it keeps a live <container-name>.<domain> -> IP mapping (plus
+// the matching reverse PTRs) by watching the engine's event stream, and
+// answers queries through the same lookup path as CustomDNSResolver.
+type ContainerDNSResolver struct {
+	NextResolver
+
+	mu               sync.RWMutex
+	mapping          map[string][]net.IP
+	reverseAddresses map[string][]string
+	hostByContainer  map[string]string
+
+	domain string
+	ttl    uint32
+
+	client *client.Client
+	cancel context.CancelFunc
+}
+
+// NewContainerDNSResolver creates new resolver instance and starts watching
+// the configured Docker/Podman socket for container start/die events.
+func NewContainerDNSResolver(cfg config.ContainerDNSConfig) ChainedResolver {
+	r := &ContainerDNSResolver{
+		mapping:          make(map[string][]net.IP),
+		reverseAddresses: make(map[string][]string),
+		hostByContainer:  make(map[string]string),
+		domain:           strings.ToLower(strings.TrimSuffix(cfg.Domain, ".")),
+		ttl:              uint32(time.Duration(cfg.CustomTTL).Seconds()),
+	}
+
+	cli, err := client.NewClientWithOpts(client.WithHost(cfg.SocketPath), client.WithAPIVersionNegotiation())
+	if err != nil {
+		logger("container_dns_resolver").WithError(err).Error("can't connect to container socket, continuing without it")
+
+		return r
+	}
+
+	r.client = cli
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+
+	if err := r.seed(ctx, cfg); err != nil {
+		logger("container_dns_resolver").WithError(err).Error("can't list containers")
+	}
+
+	go r.watch(ctx, cfg)
+
+	return r
+}
+
+// Close stops the event-stream watcher goroutine and releases the
+// Docker/Podman client. The resolver chain teardown must call this on every
+// rebuild (e.g. runtime config reload), otherwise each rebuild leaks the
+// client and its goroutine.
+func (r *ContainerDNSResolver) Close() error {
+	if r.cancel != nil {
+		r.cancel()
+	}
+
+	if r.client == nil {
+		return nil
+	}
+
+	return r.client.Close()
+}
+
+// seed populates the mapping from the containers that are already running.
+func (r *ContainerDNSResolver) seed(ctx context.Context, cfg config.ContainerDNSConfig) error {
+	containers, err := r.client.ContainerList(ctx, types.ContainerListOptions{Filters: labelFilters(cfg)})
+	if err != nil {
+		return err
+	}
+
+	for _, c := range containers {
+		r.registerContainer(ctx, c.ID)
+	}
+
+	return nil
+}
+
+func labelFilters(cfg config.ContainerDNSConfig) filters.Args {
+	f := filters.NewArgs()
+
+	for _, label := range cfg.LabelFilter {
+		f.Add("label", label)
+	}
+
+	return f
+}
+
+// watch keeps the mapping in sync with container start/die events until ctx
+// is cancelled, reconnecting with an increasing backoff whenever the
+// Docker/Podman event stream ends unexpectedly (e.g. a daemon restart) so
+// the mapping doesn't silently go stale until blocky itself is restarted.
+func (r *ContainerDNSResolver) watch(ctx context.Context, cfg config.ContainerDNSConfig) {
+	backoff := containerEventsMinBackoff
+
+	for ctx.Err() == nil {
+		connectedAt := time.Now()
+
+		err := r.watchOnce(ctx, cfg)
+		if err == nil {
+			// watchOnce only returns nil once ctx is cancelled.
+			return
+		}
+
+		if time.Since(connectedAt) > containerEventsMaxBackoff {
+			backoff = containerEventsMinBackoff
+		}
+
+		logger("container_dns_resolver").WithError(err).Warnf("container event stream interrupted, reconnecting in %s", backoff)
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return
+		}
+
+		backoff *= 2
+		if backoff > containerEventsMaxBackoff {
+			backoff = containerEventsMaxBackoff
+		}
+	}
+}
+
+// watchOnce subscribes to the container event stream once and processes
+// events until either the stream ends (non-nil error, caller should
+// reconnect) or ctx is cancelled (nil error).
+func (r *ContainerDNSResolver) watchOnce(ctx context.Context, cfg config.ContainerDNSConfig) error {
+	eventFilters := labelFilters(cfg)
+	eventFilters.Add("type", string(events.ContainerEventType))
+	eventFilters.Add("event", "start")
+	eventFilters.Add("event", "die")
+
+	msgs, errs := r.client.Events(ctx, types.EventsOptions{Filters: eventFilters})
+
+	for {
+		select {
+		case msg, ok := <-msgs:
+			if !ok {
+				return fmt.Errorf("container event stream closed")
+			}
+
+			switch msg.Action {
+			case "start":
+				r.registerContainer(ctx, msg.Actor.ID)
+			case "die":
+				r.unregisterContainer(msg.Actor.ID)
+			}
+		case err, ok := <-errs:
+			if !ok || err == nil {
+				return fmt.Errorf("container event stream closed")
+			}
+
+			return err
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func (r *ContainerDNSResolver) hostname(containerName string) string {
+	name := strings.ToLower(strings.TrimPrefix(containerName, "/"))
+
+	if r.domain == "" {
+		return name
+	}
+
+	return name + "." + r.domain
+}
+
+func (r *ContainerDNSResolver) registerContainer(ctx context.Context, containerID string) {
+	info, err := r.client.ContainerInspect(ctx, containerID)
+	if err != nil {
+		logger("container_dns_resolver").WithError(err).WithField("container", containerID).Warn("can't inspect container")
+
+		return
+	}
+
+	var ips []net.IP
+
+	for _, netCfg := range info.NetworkSettings.Networks {
+		if ip := net.ParseIP(netCfg.IPAddress); ip != nil {
+			ips = append(ips, ip)
+		}
+	}
+
+	if len(ips) == 0 {
+		return
+	}
+
+	host := r.hostname(info.Name)
+
+	r.mu.Lock()
+
+	r.removeLocked(containerID)
+
+	r.mapping[host] = ips
+	r.hostByContainer[containerID] = host
+
+	for _, ip := range ips {
+		reverse, _ := dns.ReverseAddr(ip.String())
+		r.reverseAddresses[reverse] = append(r.reverseAddresses[reverse], host)
+	}
+
+	r.mu.Unlock()
+
+	// Publish() runs every subscriber synchronously in this goroutine, and a
+	// subscriber calling back into Configuration() would deadlock on r.mu if
+	// it were still held here.
+	evt.Bus().Publish(evt.ContainerDNSMappingChanged, host)
+}
+
+func (r *ContainerDNSResolver) unregisterContainer(containerID string) {
+	r.mu.Lock()
+	host := r.removeLocked(containerID)
+	r.mu.Unlock()
+
+	if host != "" {
+		evt.Bus().Publish(evt.ContainerDNSMappingChanged, host)
+	}
+}
+
+// removeLocked drops any mapping previously registered for containerID,
+// returning the hostname that was removed, or "" if containerID wasn't
+// registered. The caller must hold r.mu.
+func (r *ContainerDNSResolver) removeLocked(containerID string) string {
+	host, found := r.hostByContainer[containerID]
+	if !found {
+		return ""
+	}
+
+	for _, ip := range r.mapping[host] {
+		reverse, _ := dns.ReverseAddr(ip.String())
+		r.reverseAddresses[reverse] = removeString(r.reverseAddresses[reverse], host)
+
+		if len(r.reverseAddresses[reverse]) == 0 {
+			delete(r.reverseAddresses, reverse)
+		}
+	}
+
+	delete(r.mapping, host)
+	delete(r.hostByContainer, containerID)
+
+	return host
+}
+
+func removeString(list []string, s string) []string {
+	result := list[:0]
+
+	for _, v := range list {
+		if v != s {
+			result = append(result, v)
+		}
+	}
+
+	return result
+}
+
+// Configuration returns current resolver configuration
+func (r *ContainerDNSResolver) Configuration() (result []string) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.client == nil {
+		return []string{"deactivated"}
+	}
+
+	for host, ips := range r.mapping {
+		result = append(result, host+" = "+ipsToString(ips))
+	}
+
+	if len(result) == 0 {
+		result = []string{"no containers mapped"}
+	}
+
+	return
+}
+
+func ipsToString(ips []net.IP) string {
+	parts := make([]string, len(ips))
+	for i, ip := range ips {
+		parts[i] = ip.String()
+	}
+
+	return strings.Join(parts, ",")
+}
+
+// Resolve answers container hostname/PTR queries from the live mapping,
+// falling through to the next resolver otherwise.
+func (r *ContainerDNSResolver) Resolve(request *model.Request) (*model.Response, error) {
+	logger := withPrefix(request.Log, "container_dns_resolver")
+
+	r.mu.RLock()
+	reverseResp := lookupReverseMapping(r.reverseAddresses, request, r.ttl)
+	ipResp := lookupIPMapping(r.mapping, request, func(string) uint32 { return r.ttl })
+	r.mu.RUnlock()
+
+	if reverseResp != nil {
+		return reverseResp, nil
+	}
+
+	if ipResp != nil {
+		return ipResp, nil
+	}
+
+	logger.WithField("resolver", Name(r.next)).Trace("go to next resolver")
+
+	return r.next.Resolve(request)
+}
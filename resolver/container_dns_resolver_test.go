@@ -0,0 +1,34 @@
+package resolver
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestContainerDNSResolverHostname(t *testing.T) {
+	r := &ContainerDNSResolver{domain: "docker.local"}
+
+	if got := r.hostname("/my-container"); got != "my-container.docker.local" {
+		t.Fatalf("hostname(/my-container) = %q, want %q", got, "my-container.docker.local")
+	}
+
+	if got := r.hostname("Mixed-Case"); got != "mixed-case.docker.local" {
+		t.Fatalf("hostname(Mixed-Case) = %q, want %q", got, "mixed-case.docker.local")
+	}
+
+	noDomain := &ContainerDNSResolver{}
+	if got := noDomain.hostname("/plain"); got != "plain" {
+		t.Fatalf("hostname(/plain) with no domain = %q, want %q", got, "plain")
+	}
+}
+
+func TestRemoveString(t *testing.T) {
+	got := removeString([]string{"a", "b", "c", "b"}, "b")
+	if want := []string{"a", "c"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("removeString() = %v, want %v", got, want)
+	}
+
+	if got := removeString([]string{"a"}, "missing"); !reflect.DeepEqual(got, []string{"a"}) {
+		t.Fatalf("removeString() with no match = %v, want unchanged slice", got)
+	}
+}
@@ -0,0 +1,289 @@
+package resolver
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/0xERR0R/blocky/evt"
+
+	"github.com/miekg/dns"
+	"github.com/sirupsen/logrus"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// fileRecords holds the DNS entries parsed from hosts-style files and RFC 1035
+// zone files. A pointer to a fileRecords instance is swapped atomically (under
+// fileStore.mu) whenever the backing files change on disk, so readers never
+// observe a partially updated set of maps.
+type fileRecords struct {
+	mapping          map[string][]net.IP
+	cnames           map[string]string
+	reverseAddresses map[string][]string
+	txt              map[string][]string
+	srv              map[string][]*dns.SRV
+
+	// ttls holds the per-owner-name TTL a zone file record was parsed with,
+	// keyed the same way as mapping/cnames. Hosts-file entries don't carry a
+	// TTL of their own and never appear here, so lookups fall back to the
+	// resolver's global customTTL.
+	ttls map[string]uint32
+}
+
+func newFileRecords() *fileRecords {
+	return &fileRecords{
+		mapping:          make(map[string][]net.IP),
+		cnames:           make(map[string]string),
+		reverseAddresses: make(map[string][]string),
+		txt:              make(map[string][]string),
+		srv:              make(map[string][]*dns.SRV),
+		ttls:             make(map[string]uint32),
+	}
+}
+
+// rememberTTL records the TTL a zone file used for name's first record, so
+// later records for the same owner name (e.g. a second A in the same rrset)
+// don't override it.
+func (r *fileRecords) rememberTTL(name string, ttl uint32) {
+	if _, found := r.ttls[name]; !found {
+		r.ttls[name] = ttl
+	}
+}
+
+// ttlFor returns the TTL a zone file specified for name, or fallback if name
+// wasn't sourced from a zone file (e.g. it came from a hosts file, which
+// doesn't carry its own TTL).
+func (r *fileRecords) ttlFor(name string, fallback uint32) uint32 {
+	if ttl, found := r.ttls[name]; found {
+		return ttl
+	}
+
+	return fallback
+}
+
+// fileStore guards the current fileRecords with a RWMutex so reloads never
+// race with concurrent Resolve calls.
+type fileStore struct {
+	mu      sync.RWMutex
+	records *fileRecords
+
+	hostsFiles []string
+	zoneFiles  []string
+
+	watcher *fsnotify.Watcher
+	logger  *logrus.Entry
+}
+
+func newFileStore(hostsFiles, zoneFiles []string) *fileStore {
+	return &fileStore{
+		records:    newFileRecords(),
+		hostsFiles: hostsFiles,
+		zoneFiles:  zoneFiles,
+		logger:     logger("custom_dns_file_source"),
+	}
+}
+
+func (s *fileStore) current() *fileRecords {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.records
+}
+
+// Close stops the file watcher and its watchLoop goroutine. It must be
+// called whenever the resolver chain is torn down (e.g. on a runtime config
+// reload), otherwise every rebuild leaks a watcher and a goroutine.
+func (s *fileStore) Close() error {
+	if s.watcher == nil {
+		return nil
+	}
+
+	return s.watcher.Close()
+}
+
+// reload re-parses all configured hosts/zone files and swaps them in as the
+// new active record set. It never mutates the previous set in place, so any
+// in-flight reader keeps using a consistent snapshot.
+func (s *fileStore) reload() error {
+	next := newFileRecords()
+
+	for _, path := range s.hostsFiles {
+		if err := loadHostsFile(path, next); err != nil {
+			return fmt.Errorf("can't load hosts file %s: %w", path, err)
+		}
+	}
+
+	for _, path := range s.zoneFiles {
+		if err := loadZoneFile(path, next); err != nil {
+			return fmt.Errorf("can't load zone file %s: %w", path, err)
+		}
+	}
+
+	s.mu.Lock()
+	s.records = next
+	s.mu.Unlock()
+
+	evt.Bus().Publish(evt.CustomDNSFileReloaded, len(next.mapping)+len(next.cnames))
+
+	return nil
+}
+
+// startWatching performs an initial load and then watches all configured
+// files for changes, reloading whenever one of them is written to.
+func (s *fileStore) startWatching() error {
+	if err := s.reload(); err != nil {
+		return err
+	}
+
+	if len(s.hostsFiles) == 0 && len(s.zoneFiles) == 0 {
+		return nil
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("can't create file watcher: %w", err)
+	}
+
+	s.watcher = watcher
+
+	for _, path := range append(append([]string{}, s.hostsFiles...), s.zoneFiles...) {
+		if err := watcher.Add(path); err != nil {
+			s.logger.WithError(err).Warnf("can't watch custom DNS file %s", path)
+		}
+	}
+
+	go s.watchLoop()
+
+	return nil
+}
+
+func (s *fileStore) watchLoop() {
+	for {
+		select {
+		case event, ok := <-s.watcher.Events:
+			if !ok {
+				return
+			}
+
+			if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Remove|fsnotify.Rename) == 0 {
+				continue
+			}
+
+			if event.Op&(fsnotify.Remove|fsnotify.Rename) != 0 {
+				// Editors like vim/atomic config-replace tools don't write the
+				// existing inode, they remove/rename it and create a new one in
+				// its place - which drops it from the watch list. Re-add it so
+				// the next edit still delivers events.
+				if err := s.watcher.Add(event.Name); err != nil {
+					s.logger.WithError(err).Warnf("can't re-watch custom DNS file %s", event.Name)
+				}
+			}
+
+			s.logger.WithField("file", event.Name).Debug("custom DNS file changed, reloading")
+
+			if err := s.reload(); err != nil {
+				s.logger.WithError(err).Error("can't reload custom DNS files")
+			}
+		case err, ok := <-s.watcher.Errors:
+			if !ok {
+				return
+			}
+
+			s.logger.WithError(err).Error("custom DNS file watcher error")
+		}
+	}
+}
+
+// loadHostsFile parses a /etc/hosts-style file: "<ip> <host1> [host2 ...]",
+// blank lines and lines starting with '#' are ignored.
+func loadHostsFile(path string, into *fileRecords) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if idx := strings.Index(line, "#"); idx >= 0 {
+			line = strings.TrimSpace(line[:idx])
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+
+		ip := net.ParseIP(fields[0])
+		if ip == nil {
+			continue
+		}
+
+		reverse, _ := dns.ReverseAddr(ip.String())
+
+		for _, host := range fields[1:] {
+			host = strings.ToLower(strings.TrimSuffix(host, "."))
+			into.mapping[host] = append(into.mapping[host], ip)
+			into.reverseAddresses[reverse] = append(into.reverseAddresses[reverse], host)
+		}
+	}
+
+	return scanner.Err()
+}
+
+// loadZoneFile parses a RFC 1035 zone file and indexes the A, AAAA, CNAME,
+// PTR, TXT and SRV records it contains so CustomDNSResolver can answer them
+// directly, alongside the statically configured mapping.
+func loadZoneFile(path string, into *fileRecords) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	parser := dns.NewZoneParser(f, "", path)
+
+	for rr, ok := parser.Next(); ok; rr, ok = parser.Next() {
+		owner := rr.Header().Name
+		name := strings.ToLower(strings.TrimSuffix(owner, "."))
+
+		switch record := rr.(type) {
+		case *dns.A:
+			into.mapping[name] = append(into.mapping[name], record.A)
+			into.rememberTTL(name, record.Hdr.Ttl)
+			reverse, _ := dns.ReverseAddr(record.A.String())
+			into.reverseAddresses[reverse] = append(into.reverseAddresses[reverse], name)
+		case *dns.AAAA:
+			into.mapping[name] = append(into.mapping[name], record.AAAA)
+			into.rememberTTL(name, record.Hdr.Ttl)
+			reverse, _ := dns.ReverseAddr(record.AAAA.String())
+			into.reverseAddresses[reverse] = append(into.reverseAddresses[reverse], name)
+		case *dns.CNAME:
+			into.cnames[name] = strings.ToLower(strings.TrimSuffix(record.Target, "."))
+			into.rememberTTL(name, record.Hdr.Ttl)
+		case *dns.PTR:
+			// PTR records are owned by the reverse (in-addr.arpa/ip6.arpa) name,
+			// which keeps its trailing dot since that's what question.Name carries.
+			into.reverseAddresses[strings.ToLower(owner)] = append(into.reverseAddresses[strings.ToLower(owner)], strings.TrimSuffix(record.Ptr, "."))
+		case *dns.TXT:
+			into.txt[name] = append(into.txt[name], record.Txt...)
+		case *dns.SRV:
+			into.srv[name] = append(into.srv[name], record)
+		}
+	}
+
+	if err := parser.Err(); err != nil {
+		return fmt.Errorf("can't parse zone file: %w", err)
+	}
+
+	return nil
+}
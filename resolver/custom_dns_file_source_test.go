@@ -0,0 +1,102 @@
+package resolver
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/miekg/dns"
+)
+
+func writeTempFile(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("can't write temp file: %v", err)
+	}
+
+	return path
+}
+
+func TestLoadHostsFile(t *testing.T) {
+	path := writeTempFile(t, "hosts", `
+# comment line, should be ignored
+127.0.0.1 localhost alias.local # trailing comment
+::1 localhost6
+
+not-an-ip host-without-ip
+`)
+
+	records := newFileRecords()
+	if err := loadHostsFile(path, records); err != nil {
+		t.Fatalf("loadHostsFile returned error: %v", err)
+	}
+
+	wantIP := net.ParseIP("127.0.0.1")
+	if ips := records.mapping["localhost"]; len(ips) != 1 || !ips[0].Equal(wantIP) {
+		t.Fatalf("mapping[localhost] = %v, want [%v]", ips, wantIP)
+	}
+
+	if ips := records.mapping["alias.local"]; len(ips) != 1 || !ips[0].Equal(wantIP) {
+		t.Fatalf("mapping[alias.local] = %v, want [%v]", ips, wantIP)
+	}
+
+	reverse, _ := dns.ReverseAddr(wantIP.String())
+	if hosts := records.reverseAddresses[reverse]; len(hosts) != 2 {
+		t.Fatalf("reverseAddresses[%s] = %v, want 2 entries", reverse, hosts)
+	}
+
+	if _, found := records.mapping["host-without-ip"]; found {
+		t.Fatalf("line with invalid IP should have been skipped")
+	}
+}
+
+func TestLoadZoneFile(t *testing.T) {
+	path := writeTempFile(t, "zone", `
+$ORIGIN example.com.
+@       3600 IN A     192.0.2.1
+www     60   IN CNAME @
+mail    120  IN A     192.0.2.2
+        120  IN TXT   "v=spam42"
+`)
+
+	records := newFileRecords()
+	if err := loadZoneFile(path, records); err != nil {
+		t.Fatalf("loadZoneFile returned error: %v", err)
+	}
+
+	if ips := records.mapping["example.com"]; len(ips) != 1 || ips[0].String() != "192.0.2.1" {
+		t.Fatalf("mapping[example.com] = %v, want [192.0.2.1]", ips)
+	}
+
+	if target := records.cnames["www.example.com"]; target != "example.com" {
+		t.Fatalf("cnames[www.example.com] = %q, want %q", target, "example.com")
+	}
+
+	if txt := records.txt["mail.example.com"]; len(txt) != 1 || txt[0] != "v=spam42" {
+		t.Fatalf("txt[mail.example.com] = %v, want [v=spam42]", txt)
+	}
+
+	// Each owner name keeps its own zone-file TTL rather than a single global
+	// one being applied to everything.
+	if ttl := records.ttlFor("example.com", 999); ttl != 3600 {
+		t.Fatalf("ttlFor(example.com) = %d, want 3600", ttl)
+	}
+
+	if ttl := records.ttlFor("www.example.com", 999); ttl != 60 {
+		t.Fatalf("ttlFor(www.example.com) = %d, want 60", ttl)
+	}
+
+	if ttl := records.ttlFor("never-seen.example.com", 999); ttl != 999 {
+		t.Fatalf("ttlFor() for an unknown name = %d, want the fallback 999", ttl)
+	}
+}
+
+func TestLoadHostsFileMissingFile(t *testing.T) {
+	records := newFileRecords()
+	if err := loadHostsFile(filepath.Join(t.TempDir(), "missing"), records); err == nil {
+		t.Fatal("expected error for missing hosts file, got nil")
+	}
+}
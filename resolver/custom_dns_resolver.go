@@ -19,7 +19,10 @@ type CustomDNSResolver struct {
 	NextResolver
 	mapping          map[string][]net.IP
 	reverseAddresses map[string][]string
+	cnames           map[string]string
+	ttlOverrides     map[string]uint32
 	ttl              uint32
+	files            *fileStore
 }
 
 // NewCustomDNSResolver creates new resolver instance
@@ -38,7 +41,46 @@ func NewCustomDNSResolver(cfg config.CustomDNSConfig) ChainedResolver {
 
 	ttl := uint32(time.Duration(cfg.CustomTTL).Seconds())
 
-	return &CustomDNSResolver{mapping: m, reverseAddresses: reverse, ttl: ttl}
+	cnames := make(map[string]string, len(cfg.Mapping.CNAMEs))
+	for domain, target := range cfg.Mapping.CNAMEs {
+		cnames[strings.ToLower(domain)] = strings.ToLower(target)
+	}
+
+	ttlOverrides := make(map[string]uint32, len(cfg.Mapping.TTLs))
+	for domain, override := range cfg.Mapping.TTLs {
+		ttlOverrides[strings.ToLower(domain)] = uint32(time.Duration(override).Seconds())
+	}
+
+	r := &CustomDNSResolver{
+		mapping:          m,
+		reverseAddresses: reverse,
+		cnames:           cnames,
+		ttlOverrides:     ttlOverrides,
+		ttl:              ttl,
+	}
+
+	if len(cfg.HostsFilePaths) > 0 || len(cfg.ZoneFilePaths) > 0 {
+		files := newFileStore(cfg.HostsFilePaths, cfg.ZoneFilePaths)
+
+		if err := files.startWatching(); err != nil {
+			logger("custom_dns_resolver").WithError(err).Error("can't watch custom DNS files, continuing without them")
+		} else {
+			r.files = files
+		}
+	}
+
+	return r
+}
+
+// Close stops the hosts/zone file watcher, if one was started. The resolver
+// chain teardown must call this on every rebuild (e.g. runtime config
+// reload), otherwise each rebuild leaks the watcher and its goroutine.
+func (r *CustomDNSResolver) Close() error {
+	if r.files == nil {
+		return nil
+	}
+
+	return r.files.Close()
 }
 
 // Configuration returns current resolver configuration
@@ -51,6 +93,11 @@ func (r *CustomDNSResolver) Configuration() (result []string) {
 		result = []string{"deactivated"}
 	}
 
+	if r.files != nil {
+		records := r.files.current()
+		result = append(result, fmt.Sprintf("hosts/zone files = %d entries, %d CNAMEs", len(records.mapping), len(records.cnames)))
+	}
+
 	return
 }
 
@@ -59,24 +106,270 @@ func isSupportedType(ip net.IP, question dns.Question) bool {
 		(strings.Contains(ip.String(), ":") && question.Qtype == dns.TypeAAAA)
 }
 
-func (r *CustomDNSResolver) handleReverseDNS(request *model.Request) *model.Response {
+// newRRHeader builds a header for a record whose type doesn't necessarily
+// match the question's qtype (e.g. a CNAME hop answering an A query), unlike
+// util.CreateHeader which ties Rrtype to the question.
+func newRRHeader(name string, rrtype uint16, ttl uint32) dns.RR_Header {
+	return dns.RR_Header{Name: dns.Fqdn(name), Rrtype: rrtype, Class: dns.ClassINET, Ttl: ttl}
+}
+
+// maxCNAMEChainDepth bounds how many CNAME hops CustomDNSResolver will
+// follow through its own mapping before giving up, so a misconfigured or
+// cyclic chain can't cause unbounded work.
+const maxCNAMEChainDepth = 10
+
+// lookupIPMapping walks up the domain labels of question (so "a.b.example.com"
+// also matches a mapping entry for "b.example.com") and returns a reply for
+// the first match, or nil if the mapping doesn't cover the queried domain at
+// all. This is the lookup path shared by CustomDNSResolver and any other
+// resolver that's backed by the same host -> IP mapping shape (e.g. the
+// container-aware resolver).
+func lookupIPMapping(mapping map[string][]net.IP, request *model.Request, ttlFor func(domain string) uint32) *model.Response {
 	question := request.Req.Question[0]
-	if question.Qtype == dns.TypePTR {
-		urls, found := r.reverseAddresses[question.Name]
+	domain := util.ExtractDomain(question)
+
+	for len(domain) > 0 {
+		ips, found := mapping[domain]
 		if found {
 			response := new(dns.Msg)
 			response.SetReply(request.Req)
 
-			for _, url := range urls {
-				h := util.CreateHeader(question, r.ttl)
-				ptr := new(dns.PTR)
-				ptr.Ptr = dns.Fqdn(url)
-				ptr.Hdr = h
-				response.Answer = append(response.Answer, ptr)
+			for _, ip := range ips {
+				if isSupportedType(ip, question) {
+					rr, _ := util.CreateAnswerFromQuestion(question, ip, ttlFor(domain))
+					response.Answer = append(response.Answer, rr)
+				}
 			}
 
+			// Either we found matching A/AAAA answers, or the mapping exists for
+			// this domain but for another qtype: either way return NOERROR.
 			return &model.Response{Res: response, RType: model.ResponseTypeCUSTOMDNS, Reason: "CUSTOM DNS"}
 		}
+
+		if i := strings.Index(domain, "."); i >= 0 {
+			domain = domain[i+1:]
+		} else {
+			break
+		}
+	}
+
+	return nil
+}
+
+// lookupReverseMapping answers a PTR query from a reverseAddresses map built
+// the same way CustomDNSResolver builds its own.
+func lookupReverseMapping(reverseAddresses map[string][]string, request *model.Request, ttl uint32) *model.Response {
+	question := request.Req.Question[0]
+	if question.Qtype != dns.TypePTR {
+		return nil
+	}
+
+	urls, found := reverseAddresses[question.Name]
+	if !found {
+		return nil
+	}
+
+	response := new(dns.Msg)
+	response.SetReply(request.Req)
+
+	for _, url := range urls {
+		h := util.CreateHeader(question, ttl)
+		ptr := new(dns.PTR)
+		ptr.Ptr = dns.Fqdn(url)
+		ptr.Hdr = h
+		response.Answer = append(response.Answer, ptr)
+	}
+
+	return &model.Response{Res: response, RType: model.ResponseTypeCUSTOMDNS, Reason: "CUSTOM DNS"}
+}
+
+// ttlFor returns the per-entry TTL override for domain if one is configured,
+// then the TTL a zone file specified for it, falling back to the resolver's
+// global customTTL otherwise.
+func (r *CustomDNSResolver) ttlFor(domain string) uint32 {
+	if ttl, found := r.ttlOverrides[domain]; found {
+		return ttl
+	}
+
+	if r.files != nil {
+		return r.files.current().ttlFor(domain, r.ttl)
+	}
+
+	return r.ttl
+}
+
+// cnameLookup resolves domain to a CNAME target, checking the statically
+// configured mapping first and falling back to the hosts/zone file derived
+// one, so a chain can freely mix both sources.
+func (r *CustomDNSResolver) cnameLookup(domain string) (string, bool) {
+	if target, found := r.cnames[domain]; found {
+		return target, true
+	}
+
+	if r.files != nil {
+		if target, found := r.files.current().cnames[domain]; found {
+			return target, true
+		}
+	}
+
+	return "", false
+}
+
+// ipLookup resolves domain to its configured IPs, checking the statically
+// configured mapping first and falling back to the hosts/zone file derived
+// one.
+func (r *CustomDNSResolver) ipLookup(domain string) ([]net.IP, bool) {
+	if ips, found := r.mapping[domain]; found {
+		return ips, true
+	}
+
+	if r.files != nil {
+		if ips, found := r.files.current().mapping[domain]; found {
+			return ips, true
+		}
+	}
+
+	return nil, false
+}
+
+// resolveCNAMEChain follows a chain of CNAME entries - from the statically
+// configured mapping and/or the hosts/zone files, which share one chain so
+// either can point at the other - starting at the queried domain, appending
+// a CNAME record for each hop. It stops at the first hop that either
+// resolves to an IP (in which case the matching A/AAAA record is appended
+// too) or that isn't a CNAME target we know about, in which case the
+// unresolved tail is handed to r.next and its answer merged onto the chain
+// we've already built. Returns nil, nil if the queried domain isn't a CNAME
+// entry at all.
+func (r *CustomDNSResolver) resolveCNAMEChain(request *model.Request) (*model.Response, error) {
+	question := request.Req.Question[0]
+	domain := strings.ToLower(util.ExtractDomain(question))
+
+	target, found := r.cnameLookup(domain)
+	if !found {
+		return nil, nil
+	}
+
+	response := new(dns.Msg)
+	response.SetReply(request.Req)
+
+	seen := map[string]bool{domain: true}
+
+	for depth := 0; found && depth < maxCNAMEChainDepth; depth++ {
+		if seen[target] {
+			logger("custom_dns_resolver").WithField("domain", domain).Warn("CNAME loop detected, stopping chain resolution")
+
+			break
+		}
+
+		seen[target] = true
+
+		response.Answer = append(response.Answer, &dns.CNAME{
+			Hdr:    newRRHeader(domain, dns.TypeCNAME, r.ttlFor(domain)),
+			Target: dns.Fqdn(target),
+		})
+
+		domain = target
+		target, found = r.cnameLookup(domain)
+	}
+
+	if ips, found := r.ipLookup(domain); found {
+		finalQuestion := dns.Question{Name: dns.Fqdn(domain), Qtype: question.Qtype, Qclass: question.Qclass}
+
+		for _, ip := range ips {
+			if isSupportedType(ip, finalQuestion) {
+				rr, _ := util.CreateAnswerFromQuestion(finalQuestion, ip, r.ttlFor(domain))
+				response.Answer = append(response.Answer, rr)
+			}
+		}
+
+		return &model.Response{Res: response, RType: model.ResponseTypeCUSTOMDNS, Reason: "CUSTOM DNS"}, nil
+	}
+
+	// The chain bottoms out on a name we don't have a static answer for:
+	// ask the next resolver to finish it and merge the result onto ours.
+	tailRequest := *request
+	tailRequest.Req = request.Req.Copy()
+	tailRequest.Req.Question[0].Name = dns.Fqdn(domain)
+
+	tailResp, err := r.next.Resolve(&tailRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	response.Answer = append(response.Answer, tailResp.Res.Answer...)
+	response.Rcode = tailResp.Res.Rcode
+
+	return &model.Response{Res: response, RType: model.ResponseTypeCUSTOMDNS, Reason: "CUSTOM DNS"}, nil
+}
+
+func (r *CustomDNSResolver) handleReverseDNS(request *model.Request) *model.Response {
+	if resp := lookupReverseMapping(r.reverseAddresses, request, r.ttl); resp != nil {
+		return resp
+	}
+
+	if r.files != nil {
+		return lookupReverseMapping(r.files.current().reverseAddresses, request, r.ttl)
+	}
+
+	return nil
+}
+
+// resolveFromFiles answers a query directly from the hosts/zone file derived
+// records, returning nil if nothing matches so the caller can fall through
+// to the next resolver. The statically configured mapping is checked before
+// this, by Resolve. CNAME entries
+// (from either source) are handled earlier, by resolveCNAMEChain.
+func (r *CustomDNSResolver) resolveFromFiles(request *model.Request) *model.Response {
+	if r.files == nil {
+		return nil
+	}
+
+	records := r.files.current()
+	question := request.Req.Question[0]
+	domain := strings.ToLower(util.ExtractDomain(question))
+
+	switch question.Qtype {
+	case dns.TypeA, dns.TypeAAAA:
+		if len(records.mapping) > 0 {
+			return lookupIPMapping(records.mapping, request, func(domain string) uint32 { return records.ttlFor(domain, r.ttl) })
+		}
+	case dns.TypeTXT:
+		txt, found := records.txt[domain]
+		if !found {
+			return nil
+		}
+
+		response := new(dns.Msg)
+		response.SetReply(request.Req)
+
+		for _, t := range txt {
+			h := util.CreateHeader(question, records.ttlFor(domain, r.ttl))
+			response.Answer = append(response.Answer, &dns.TXT{Hdr: h, Txt: []string{t}})
+		}
+
+		return &model.Response{Res: response, RType: model.ResponseTypeCUSTOMDNS, Reason: "CUSTOM DNS"}
+	case dns.TypeSRV:
+		srvRecords, found := records.srv[domain]
+		if !found {
+			return nil
+		}
+
+		response := new(dns.Msg)
+		response.SetReply(request.Req)
+
+		for _, srv := range srvRecords {
+			h := util.CreateHeader(question, records.ttlFor(domain, r.ttl))
+			response.Answer = append(response.Answer, &dns.SRV{
+				Hdr:      h,
+				Priority: srv.Priority,
+				Weight:   srv.Weight,
+				Port:     srv.Port,
+				Target:   srv.Target,
+			})
+		}
+
+		return &model.Response{Res: response, RType: model.ResponseTypeCUSTOMDNS, Reason: "CUSTOM DNS"}
 	}
 
 	return nil
@@ -91,44 +384,25 @@ func (r *CustomDNSResolver) Resolve(request *model.Request) (*model.Response, er
 		return reverseResp, nil
 	}
 
-	if len(r.mapping) > 0 {
-		response := new(dns.Msg)
-		response.SetReply(request.Req)
-
-		question := request.Req.Question[0]
-		domain := util.ExtractDomain(question)
-
-		for len(domain) > 0 {
-			ips, found := r.mapping[domain]
-			if found {
-				for _, ip := range ips {
-					if isSupportedType(ip, question) {
-						rr, _ := util.CreateAnswerFromQuestion(question, ip, r.ttl)
-						response.Answer = append(response.Answer, rr)
-					}
-				}
-
-				if len(response.Answer) > 0 {
-					logger.WithFields(logrus.Fields{
-						"answer": util.AnswerToString(response.Answer),
-						"domain": domain,
-					}).Debugf("returning custom dns entry")
+	if cnameResp, err := r.resolveCNAMEChain(request); cnameResp != nil || err != nil {
+		return cnameResp, err
+	}
 
-					return &model.Response{Res: response, RType: model.ResponseTypeCUSTOMDNS, Reason: "CUSTOM DNS"}, nil
-				}
+	if len(r.mapping) > 0 {
+		if resp := lookupIPMapping(r.mapping, request, r.ttlFor); resp != nil {
+			logger.WithFields(logrus.Fields{
+				"answer": util.AnswerToString(resp.Res.Answer),
+				"domain": util.ExtractDomain(request.Req.Question[0]),
+			}).Debugf("returning custom dns entry")
 
-				// Mapping exists for this domain, but for another type
-				// return NOERROR with empty result
+			return resp, nil
+		}
+	}
 
-				return &model.Response{Res: response, RType: model.ResponseTypeCUSTOMDNS, Reason: "CUSTOM DNS"}, nil
-			}
+	if fileResp := r.resolveFromFiles(request); fileResp != nil {
+		logger.WithField("answer", util.AnswerToString(fileResp.Res.Answer)).Debugf("returning custom dns entry from hosts/zone file")
 
-			if i := strings.Index(domain, "."); i >= 0 {
-				domain = domain[i+1:]
-			} else {
-				break
-			}
-		}
+		return fileResp, nil
 	}
 
 	logger.WithField("resolver", Name(r.next)).Trace("go to next resolver")
@@ -0,0 +1,140 @@
+package resolver
+
+import (
+	"net"
+	"testing"
+
+	"github.com/0xERR0R/blocky/model"
+
+	"github.com/miekg/dns"
+)
+
+func newARequest(name string) *model.Request {
+	msg := new(dns.Msg)
+	msg.SetQuestion(dns.Fqdn(name), dns.TypeA)
+
+	return &model.Request{Req: msg}
+}
+
+func TestResolveCNAMEChainFollowsChainToIP(t *testing.T) {
+	r := &CustomDNSResolver{
+		mapping: map[string][]net.IP{"target.example.com": {net.ParseIP("192.0.2.1")}},
+		cnames: map[string]string{
+			"alias.example.com": "target.example.com",
+		},
+		ttl: 300,
+	}
+
+	resp, err := r.resolveCNAMEChain(newARequest("alias.example.com"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if resp == nil {
+		t.Fatal("expected a response, got nil")
+	}
+
+	if len(resp.Res.Answer) != 2 {
+		t.Fatalf("expected 1 CNAME + 1 A record, got %d answers: %v", len(resp.Res.Answer), resp.Res.Answer)
+	}
+
+	if _, ok := resp.Res.Answer[0].(*dns.CNAME); !ok {
+		t.Fatalf("expected first answer to be a CNAME, got %T", resp.Res.Answer[0])
+	}
+
+	if a, ok := resp.Res.Answer[1].(*dns.A); !ok || !a.A.Equal(net.ParseIP("192.0.2.1")) {
+		t.Fatalf("expected second answer to be A 192.0.2.1, got %v", resp.Res.Answer[1])
+	}
+}
+
+func TestResolveCNAMEChainNotFound(t *testing.T) {
+	r := &CustomDNSResolver{}
+
+	resp, err := r.resolveCNAMEChain(newARequest("unrelated.example.com"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if resp != nil {
+		t.Fatalf("expected nil response for a domain with no CNAME entry, got %v", resp)
+	}
+}
+
+func TestResolveCNAMEChainDetectsLoop(t *testing.T) {
+	r := &CustomDNSResolver{
+		cnames: map[string]string{
+			"a.example.com": "b.example.com",
+			"b.example.com": "a.example.com",
+		},
+		// Give the domain the loop is broken on a direct IP answer, so the
+		// break doesn't fall through to r.next.
+		mapping: map[string][]net.IP{"b.example.com": {net.ParseIP("192.0.2.5")}},
+		ttl:     300,
+	}
+
+	resp, err := r.resolveCNAMEChain(newARequest("a.example.com"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if resp == nil {
+		t.Fatal("expected a response even when the chain loops")
+	}
+
+	// The loop must be broken before maxCNAMEChainDepth forces a cutoff, i.e.
+	// well under 2*maxCNAMEChainDepth CNAME hops.
+	if len(resp.Res.Answer) >= 2*maxCNAMEChainDepth {
+		t.Fatalf("CNAME loop was not detected, got %d answers", len(resp.Res.Answer))
+	}
+}
+
+func TestResolveCNAMEChainBoundsDepth(t *testing.T) {
+	cnames := make(map[string]string)
+
+	for i := 0; i < maxCNAMEChainDepth+5; i++ {
+		cnames[hostForDepth(i)] = hostForDepth(i + 1)
+	}
+
+	// Give the domain the chain is stopped at (after maxCNAMEChainDepth hops)
+	// a direct IP answer, so the cutoff doesn't fall through to r.next.
+	r := &CustomDNSResolver{
+		cnames:  cnames,
+		mapping: map[string][]net.IP{hostForDepth(maxCNAMEChainDepth): {net.ParseIP("192.0.2.9")}},
+		ttl:     300,
+	}
+
+	resp, err := r.resolveCNAMEChain(newARequest(hostForDepth(0)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if resp == nil {
+		t.Fatal("expected a response, got nil")
+	}
+
+	// maxCNAMEChainDepth CNAME hops plus the final A record.
+	if want := maxCNAMEChainDepth + 1; len(resp.Res.Answer) != want {
+		t.Fatalf("expected %d answers (chain capped at maxCNAMEChainDepth), got %d: %v", want, len(resp.Res.Answer), resp.Res.Answer)
+	}
+}
+
+func hostForDepth(i int) string {
+	return dns.Fqdn(string(rune('a'+i%26))) + "example.com"
+}
+
+func TestCNAMELookupFallsBackToFiles(t *testing.T) {
+	r := &CustomDNSResolver{
+		cnames: map[string]string{},
+		files:  newFileStore(nil, nil),
+	}
+	r.files.records.cnames["from-file.example.com"] = "target.example.com"
+
+	target, found := r.cnameLookup("from-file.example.com")
+	if !found || target != "target.example.com" {
+		t.Fatalf("cnameLookup() = (%q, %v), want (%q, true)", target, found, "target.example.com")
+	}
+
+	if _, found := r.cnameLookup("nowhere.example.com"); found {
+		t.Fatal("cnameLookup() found a target for a domain with no entry in either source")
+	}
+}
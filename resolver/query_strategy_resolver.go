@@ -0,0 +1,131 @@
+package resolver
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/0xERR0R/blocky/config"
+	"github.com/0xERR0R/blocky/model"
+
+	"github.com/miekg/dns"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var suppressedAnswersTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "blocky_query_strategy_suppressed_answers_total",
+	Help: "Number of A/AAAA answers suppressed by the configured query strategy",
+}, []string{"type"})
+
+// QueryStrategyResolver forces answers down to the configured IP family
+// (UseIP, UseIPv4 or UseIPv6), regardless of which resolver produced them. It
+// sits in front of CustomDNSResolver/blocking/upstream in the chain and
+// filters whatever they returned, dropping AAAA answers on IPv4-only networks
+// (or the reverse) and synthesizing a NOERROR/empty response instead of
+// letting an empty answer section turn into NXDOMAIN, so clients fall back
+// to the remaining family cleanly.
+type QueryStrategyResolver struct {
+	NextResolver
+	strategy     config.QueryStrategy
+	clientGroups map[string]config.QueryStrategy
+}
+
+// NewQueryStrategyResolver creates new resolver instance
+func NewQueryStrategyResolver(cfg config.QueryStrategyConfig) ChainedResolver {
+	groups := make(map[string]config.QueryStrategy, len(cfg.ClientGroupsStrategy))
+
+	for group, strategy := range cfg.ClientGroupsStrategy {
+		groups[strings.ToLower(group)] = strategy
+	}
+
+	return &QueryStrategyResolver{strategy: cfg.QueryStrategy, clientGroups: groups}
+}
+
+// Configuration returns current resolver configuration
+func (r *QueryStrategyResolver) Configuration() (result []string) {
+	if r.strategy == "" && len(r.clientGroups) == 0 {
+		return []string{"deactivated"}
+	}
+
+	result = append(result, fmt.Sprintf("default strategy = %s", r.strategy))
+
+	for group, strategy := range r.clientGroups {
+		result = append(result, fmt.Sprintf("%s = %s", group, strategy))
+	}
+
+	return
+}
+
+// strategyFor returns the query strategy that applies to request, preferring
+// a per-client-group override over the global default.
+func (r *QueryStrategyResolver) strategyFor(request *model.Request) config.QueryStrategy {
+	for _, name := range request.ClientNames {
+		if strategy, found := r.clientGroups[strings.ToLower(name)]; found {
+			return strategy
+		}
+	}
+
+	return r.strategy
+}
+
+// filterByStrategy drops the answers that don't match strategy, returning
+// the filtered slice and how many records were suppressed.
+func filterByStrategy(answer []dns.RR, strategy config.QueryStrategy) ([]dns.RR, int) {
+	if strategy == "" || strategy == config.QueryStrategyUseIP {
+		return answer, 0
+	}
+
+	filtered := make([]dns.RR, 0, len(answer))
+	suppressed := 0
+
+	for _, rr := range answer {
+		switch rr.Header().Rrtype {
+		case dns.TypeAAAA:
+			if strategy == config.QueryStrategyUseIPv4 {
+				suppressedAnswersTotal.WithLabelValues("AAAA").Inc()
+				suppressed++
+
+				continue
+			}
+		case dns.TypeA:
+			if strategy == config.QueryStrategyUseIPv6 {
+				suppressedAnswersTotal.WithLabelValues("A").Inc()
+				suppressed++
+
+				continue
+			}
+		}
+
+		filtered = append(filtered, rr)
+	}
+
+	return filtered, suppressed
+}
+
+// Resolve asks the next resolver first, then filters its answer down to the
+// configured query strategy.
+func (r *QueryStrategyResolver) Resolve(request *model.Request) (*model.Response, error) {
+	logger := withPrefix(request.Log, "query_strategy_resolver")
+
+	response, err := r.next.Resolve(request)
+	if err != nil || response == nil || response.Res == nil {
+		return response, err
+	}
+
+	strategy := r.strategyFor(request)
+
+	filtered, suppressed := filterByStrategy(response.Res.Answer, strategy)
+	if suppressed == 0 {
+		return response, nil
+	}
+
+	logger.WithField("strategy", strategy).Debugf("suppressed %d answers not matching query strategy", suppressed)
+
+	response.Res.Answer = filtered
+
+	if len(filtered) == 0 {
+		response.Res.Rcode = dns.RcodeSuccess
+	}
+
+	return response, nil
+}
@@ -0,0 +1,66 @@
+package resolver
+
+import (
+	"net"
+	"testing"
+
+	"github.com/0xERR0R/blocky/config"
+
+	"github.com/miekg/dns"
+)
+
+func aRecord(name string) dns.RR {
+	return &dns.A{Hdr: dns.RR_Header{Name: name, Rrtype: dns.TypeA}, A: net.ParseIP("192.0.2.1")}
+}
+
+func aaaaRecord(name string) dns.RR {
+	return &dns.AAAA{Hdr: dns.RR_Header{Name: name, Rrtype: dns.TypeAAAA}, AAAA: net.ParseIP("2001:db8::1")}
+}
+
+func TestFilterByStrategyUseIP(t *testing.T) {
+	answer := []dns.RR{aRecord("example.com."), aaaaRecord("example.com.")}
+
+	filtered, suppressed := filterByStrategy(answer, config.QueryStrategyUseIP)
+	if suppressed != 0 || len(filtered) != 2 {
+		t.Fatalf("UseIP should pass everything through, got %d filtered, %d suppressed", len(filtered), suppressed)
+	}
+}
+
+func TestFilterByStrategyUseIPv4(t *testing.T) {
+	answer := []dns.RR{aRecord("example.com."), aaaaRecord("example.com.")}
+
+	filtered, suppressed := filterByStrategy(answer, config.QueryStrategyUseIPv4)
+	if suppressed != 1 {
+		t.Fatalf("expected 1 suppressed AAAA record, got %d", suppressed)
+	}
+
+	if len(filtered) != 1 {
+		t.Fatalf("expected 1 remaining answer, got %d", len(filtered))
+	}
+
+	if filtered[0].Header().Rrtype != dns.TypeA {
+		t.Fatalf("expected remaining answer to be an A record, got rrtype %d", filtered[0].Header().Rrtype)
+	}
+}
+
+func TestFilterByStrategyUseIPv6(t *testing.T) {
+	answer := []dns.RR{aRecord("example.com."), aaaaRecord("example.com.")}
+
+	filtered, suppressed := filterByStrategy(answer, config.QueryStrategyUseIPv6)
+	if suppressed != 1 {
+		t.Fatalf("expected 1 suppressed A record, got %d", suppressed)
+	}
+
+	if len(filtered) != 1 || filtered[0].Header().Rrtype != dns.TypeAAAA {
+		t.Fatalf("expected 1 remaining AAAA answer, got %v", filtered)
+	}
+}
+
+func TestFilterByStrategyEmptyIsUseIP(t *testing.T) {
+	answer := []dns.RR{aRecord("example.com."), aaaaRecord("example.com.")}
+
+	filtered, suppressed := filterByStrategy(answer, "")
+	if suppressed != 0 || len(filtered) != 2 {
+		t.Fatalf("empty strategy should behave like UseIP, got %d filtered, %d suppressed", len(filtered), suppressed)
+	}
+}
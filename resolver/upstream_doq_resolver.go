@@ -0,0 +1,208 @@
+package resolver
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/0xERR0R/blocky/config"
+	"github.com/0xERR0R/blocky/model"
+
+	"github.com/miekg/dns"
+	"github.com/quic-go/quic-go"
+)
+
+// doqALPN is the ALPN token RFC 9250 reserves for DNS-over-QUIC.
+const doqALPN = "doq"
+
+// DoQUpstreamResolver forwards queries to a single upstream over
+// DNS-over-QUIC (RFC 9250). It keeps one QUIC connection open across
+// requests - reusing its 0-RTT session ticket the same way the DoH/DoT
+// upstreams reuse their TLS sessions - and only redials when the connection
+// has gone away.
+//
+// The listener-side counterpart - accepting `quic://` binds with ALPN "doq"
+// and serving the same per-stream RFC 9250 framing - is server.DoQListener.
+type DoQUpstreamResolver struct {
+	upstream   config.Upstream
+	tlsConfig  *tls.Config
+	tokenStore quic.TokenStore
+
+	mu   sync.Mutex
+	conn quic.Connection
+}
+
+// NewDoQUpstreamResolver creates a new resolver instance for a `quic://` upstream.
+func NewDoQUpstreamResolver(cfg config.Upstream, tlsConfig *tls.Config) (*DoQUpstreamResolver, error) {
+	if cfg.Host == "" {
+		return nil, fmt.Errorf("can't create DoQ upstream resolver: missing host")
+	}
+
+	if tlsConfig == nil {
+		tlsConfig = new(tls.Config)
+	} else {
+		tlsConfig = tlsConfig.Clone()
+	}
+
+	tlsConfig.NextProtos = []string{doqALPN}
+	tlsConfig.ServerName = cfg.Host
+	// ClientSessionCache + TokenStore are what actually make the 0-RTT resumption
+	// promised above happen: without them, DialAddrEarly has nothing to resume
+	// from and silently falls back to a full handshake on every redial.
+	tlsConfig.ClientSessionCache = tls.NewLRUClientSessionCache(0)
+
+	return &DoQUpstreamResolver{
+		upstream:   cfg,
+		tlsConfig:  tlsConfig,
+		tokenStore: quic.NewLRUTokenStore(1, 1),
+	}, nil
+}
+
+// Configuration returns current resolver configuration
+func (r *DoQUpstreamResolver) Configuration() []string {
+	return []string{r.String()}
+}
+
+func (r *DoQUpstreamResolver) String() string {
+	return fmt.Sprintf("doq upstream '%s'", net.JoinHostPort(r.upstream.Host, fmt.Sprint(r.upstream.Port)))
+}
+
+// connection returns the current QUIC connection, dialing (or re-dialing,
+// with 0-RTT if the TLS session cache has a ticket for this upstream) if
+// there isn't a live one yet.
+func (r *DoQUpstreamResolver) connection(ctx context.Context) (quic.Connection, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.conn != nil {
+		select {
+		case <-r.conn.Context().Done():
+			r.conn = nil
+		default:
+			return r.conn, nil
+		}
+	}
+
+	addr := net.JoinHostPort(r.upstream.Host, fmt.Sprint(r.upstream.Port))
+
+	conn, err := quic.DialAddrEarly(ctx, addr, r.tlsConfig, &quic.Config{TokenStore: r.tokenStore})
+	if err != nil {
+		return nil, err
+	}
+
+	r.conn = conn
+
+	return conn, nil
+}
+
+// invalidate drops the cached connection so the next query redials, used
+// whenever a stream operation fails in a way that suggests the connection
+// itself is broken.
+func (r *DoQUpstreamResolver) invalidate() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.conn != nil {
+		_ = r.conn.CloseWithError(0, "")
+		r.conn = nil
+	}
+}
+
+// Resolve sends request to the DoQ upstream on its own QUIC stream, per RFC
+// 9250 section 4.2: one query/response pair per stream, framed with a 2-byte
+// big-endian length prefix, and a DNS ID of 0 to maximise cache sharing.
+func (r *DoQUpstreamResolver) Resolve(request *model.Request) (*model.Response, error) {
+	ctx := context.Background()
+
+	if timeout := time.Duration(r.upstream.Timeout); timeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	conn, err := r.connection(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("can't connect to %s: %w", r, err)
+	}
+
+	stream, err := conn.OpenStreamSync(ctx)
+	if err != nil {
+		r.invalidate()
+
+		return nil, fmt.Errorf("can't open stream to %s: %w", r, err)
+	}
+	defer stream.Close()
+
+	query := request.Req.Copy()
+	query.Id = 0
+
+	packed, err := query.Pack()
+	if err != nil {
+		return nil, fmt.Errorf("can't pack DoQ query: %w", err)
+	}
+
+	if err := writeDoQMessage(stream, packed); err != nil {
+		r.invalidate()
+
+		return nil, fmt.Errorf("can't write DoQ query to %s: %w", r, err)
+	}
+
+	if err := stream.Close(); err != nil {
+		r.invalidate()
+
+		return nil, fmt.Errorf("can't close DoQ write side to %s: %w", r, err)
+	}
+
+	raw, err := readDoQMessage(stream)
+	if err != nil {
+		r.invalidate()
+
+		return nil, fmt.Errorf("can't read DoQ response from %s: %w", r, err)
+	}
+
+	response := new(dns.Msg)
+	if err := response.Unpack(raw); err != nil {
+		return nil, fmt.Errorf("can't unpack DoQ response from %s: %w", r, err)
+	}
+
+	response.Id = request.Req.Id
+
+	return &model.Response{Res: response, RType: model.ResponseTypeRESOLVED, Reason: r.String()}, nil
+}
+
+// doqStream is the subset of quic.Stream used for framing; satisfied by the
+// real quic.Stream returned from OpenStreamSync.
+type doqStream interface {
+	io.Reader
+	io.Writer
+}
+
+func writeDoQMessage(stream doqStream, msg []byte) error {
+	framed := make([]byte, 2+len(msg))
+	binary.BigEndian.PutUint16(framed, uint16(len(msg)))
+	copy(framed[2:], msg)
+
+	_, err := stream.Write(framed)
+
+	return err
+}
+
+func readDoQMessage(stream doqStream) ([]byte, error) {
+	prefix := make([]byte, 2)
+	if _, err := io.ReadFull(stream, prefix); err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, binary.BigEndian.Uint16(prefix))
+	if _, err := io.ReadFull(stream, buf); err != nil {
+		return nil, err
+	}
+
+	return buf, nil
+}
@@ -0,0 +1,64 @@
+package resolver
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestWriteDoQMessageFramesWithLengthPrefix(t *testing.T) {
+	var buf bytes.Buffer
+
+	msg := []byte("hello dns")
+	if err := writeDoQMessage(&buf, msg); err != nil {
+		t.Fatalf("writeDoQMessage returned error: %v", err)
+	}
+
+	want := append([]byte{0x00, byte(len(msg))}, msg...)
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Fatalf("writeDoQMessage wrote %v, want %v", buf.Bytes(), want)
+	}
+}
+
+func TestReadDoQMessageRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+
+	msg := []byte("a reasonably sized dns response payload")
+	if err := writeDoQMessage(&buf, msg); err != nil {
+		t.Fatalf("writeDoQMessage returned error: %v", err)
+	}
+
+	got, err := readDoQMessage(&buf)
+	if err != nil {
+		t.Fatalf("readDoQMessage returned error: %v", err)
+	}
+
+	if !bytes.Equal(got, msg) {
+		t.Fatalf("readDoQMessage() = %v, want %v", got, msg)
+	}
+}
+
+func TestReadDoQMessageTruncatedPrefix(t *testing.T) {
+	var buf bytes.Buffer
+	buf.Write([]byte{0x00})
+
+	_, err := readDoQMessage(&buf)
+	if err == nil {
+		t.Fatal("expected an error reading a truncated length prefix, got nil")
+	}
+
+	if err != io.ErrUnexpectedEOF {
+		t.Fatalf("expected io.ErrUnexpectedEOF, got %v", err)
+	}
+}
+
+func TestReadDoQMessageTruncatedBody(t *testing.T) {
+	var buf bytes.Buffer
+	// Length prefix claims 10 bytes but only 2 follow.
+	buf.Write([]byte{0x00, 0x0a, 0x01, 0x02})
+
+	_, err := readDoQMessage(&buf)
+	if err == nil {
+		t.Fatal("expected an error reading a truncated body, got nil")
+	}
+}
@@ -0,0 +1,177 @@
+// Package server hosts the listener protocols blocky accepts queries on.
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/0xERR0R/blocky/config"
+	"github.com/0xERR0R/blocky/model"
+	"github.com/0xERR0R/blocky/resolver"
+
+	"github.com/miekg/dns"
+	"github.com/quic-go/quic-go"
+	"github.com/sirupsen/logrus"
+)
+
+// doqALPN is the ALPN token RFC 9250 reserves for DNS-over-QUIC - the
+// listener only accepts connections that negotiate it.
+const doqALPN = "doq"
+
+// DoQListener serves DNS-over-QUIC (RFC 9250) queries on a `quic://` bind
+// address - the listener-side counterpart to resolver.DoQUpstreamResolver.
+// It accepts connections, reads one framed query per stream (per RFC 9250
+// section 4.2), resolves it through the configured resolver chain, and
+// writes the framed response back on the same stream.
+type DoQListener struct {
+	addr      string
+	tlsConfig *tls.Config
+	resolver  resolver.Resolver
+
+	mu       sync.Mutex
+	listener *quic.EarlyListener
+}
+
+// NewDoQListener creates a listener for cfg.Address, loading the configured
+// certificate. Queries are resolved by next, which is normally the same
+// chain resolver.NewResolverChain built for the other listeners.
+func NewDoQListener(cfg config.DoQListener, next resolver.Resolver) (*DoQListener, error) {
+	if cfg.Address == "" {
+		return nil, fmt.Errorf("can't create DoQ listener: missing address")
+	}
+
+	cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("can't load DoQ listener certificate: %w", err)
+	}
+
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		NextProtos:   []string{doqALPN},
+	}
+
+	return &DoQListener{addr: cfg.Address, tlsConfig: tlsConfig, resolver: next}, nil
+}
+
+// Start binds the listener's address and serves queries until ctx is
+// cancelled or Close is called. It blocks until the accept loop ends.
+func (l *DoQListener) Start(ctx context.Context) error {
+	ln, err := quic.ListenAddrEarly(l.addr, l.tlsConfig, &quic.Config{})
+	if err != nil {
+		return fmt.Errorf("can't listen on %s: %w", l.addr, err)
+	}
+
+	l.mu.Lock()
+	l.listener = ln
+	l.mu.Unlock()
+
+	for {
+		conn, err := ln.Accept(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			return fmt.Errorf("can't accept DoQ connection: %w", err)
+		}
+
+		go l.serveConn(ctx, conn)
+	}
+}
+
+// Close stops accepting new connections and queries. Streams already being
+// served are left to finish on their own.
+func (l *DoQListener) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.listener == nil {
+		return nil
+	}
+
+	return l.listener.Close()
+}
+
+func (l *DoQListener) serveConn(ctx context.Context, conn quic.Connection) {
+	for {
+		stream, err := conn.AcceptStream(ctx)
+		if err != nil {
+			return
+		}
+
+		go l.serveStream(stream)
+	}
+}
+
+func (l *DoQListener) serveStream(stream quic.Stream) {
+	defer stream.Close()
+
+	raw, err := readDoQMessage(stream)
+	if err != nil {
+		logrus.WithError(err).Debug("can't read DoQ query")
+
+		return
+	}
+
+	query := new(dns.Msg)
+	if err := query.Unpack(raw); err != nil {
+		logrus.WithError(err).Debug("can't unpack DoQ query")
+
+		return
+	}
+
+	response, err := l.resolver.Resolve(&model.Request{Req: query})
+	if err != nil {
+		logrus.WithError(err).Debug("can't resolve DoQ query")
+
+		return
+	}
+
+	response.Res.Id = query.Id
+
+	packed, err := response.Res.Pack()
+	if err != nil {
+		logrus.WithError(err).Debug("can't pack DoQ response")
+
+		return
+	}
+
+	if err := writeDoQMessage(stream, packed); err != nil {
+		logrus.WithError(err).Debug("can't write DoQ response")
+	}
+}
+
+// doqStream is the subset of quic.Stream used for framing; satisfied by the
+// real quic.Stream returned from AcceptStream.
+type doqStream interface {
+	io.Reader
+	io.Writer
+}
+
+func writeDoQMessage(stream doqStream, msg []byte) error {
+	framed := make([]byte, 2+len(msg))
+	binary.BigEndian.PutUint16(framed, uint16(len(msg)))
+	copy(framed[2:], msg)
+
+	_, err := stream.Write(framed)
+
+	return err
+}
+
+func readDoQMessage(stream doqStream) ([]byte, error) {
+	prefix := make([]byte, 2)
+	if _, err := io.ReadFull(stream, prefix); err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, binary.BigEndian.Uint16(prefix))
+	if _, err := io.ReadFull(stream, buf); err != nil {
+		return nil, err
+	}
+
+	return buf, nil
+}
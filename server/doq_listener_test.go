@@ -0,0 +1,51 @@
+package server
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/0xERR0R/blocky/config"
+)
+
+func TestNewDoQListenerRequiresAddress(t *testing.T) {
+	_, err := NewDoQListener(config.DoQListener{}, nil)
+	if err == nil {
+		t.Fatal("expected an error for a missing address, got nil")
+	}
+}
+
+func TestNewDoQListenerRejectsMissingCert(t *testing.T) {
+	cfg := config.DoQListener{
+		Address:  ":8853",
+		CertFile: filepath.Join(t.TempDir(), "missing-cert.pem"),
+		KeyFile:  filepath.Join(t.TempDir(), "missing-key.pem"),
+	}
+
+	_, err := NewDoQListener(cfg, nil)
+	if err == nil {
+		t.Fatal("expected an error for a missing certificate, got nil")
+	}
+}
+
+func TestWriteDoQMessageFramesWithLengthPrefix(t *testing.T) {
+	var buf bytes.Buffer
+
+	msg := []byte("hello dns")
+	if err := writeDoQMessage(&buf, msg); err != nil {
+		t.Fatalf("writeDoQMessage returned error: %v", err)
+	}
+
+	if want := append([]byte{0x00, byte(len(msg))}, msg...); !bytes.Equal(buf.Bytes(), want) {
+		t.Fatalf("writeDoQMessage wrote %v, want %v", buf.Bytes(), want)
+	}
+
+	got, err := readDoQMessage(&buf)
+	if err != nil {
+		t.Fatalf("readDoQMessage returned error: %v", err)
+	}
+
+	if !bytes.Equal(got, msg) {
+		t.Fatalf("readDoQMessage() = %v, want %v", got, msg)
+	}
+}